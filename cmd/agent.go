@@ -0,0 +1,67 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/loadimpact/k6/core/remote"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var agentAddress string
+
+// agentCmd represents the agent command.
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Start a remote execution worker",
+	Long: `Start a remote execution worker.
+
+This waits for a coordinator (a "k6 run --execution=cluster=..." invocation)
+to dispatch a test to it over HTTP, runs that test locally, and reports its
+progress back so it can be aggregated into the coordinator's own output.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.WithField("address", agentAddress).Info("Agent listening")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		sigC := make(chan os.Signal, 1)
+		signal.Notify(sigC, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(sigC)
+		go func() {
+			<-sigC
+			cancel()
+		}()
+
+		return remote.NewAgent().Serve(ctx, agentAddress)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(agentCmd)
+	agentCmd.Flags().StringVar(&agentAddress, "address", ":6566", "`address` to listen for coordinators on")
+}
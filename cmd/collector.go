@@ -0,0 +1,59 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/stats/cloud"
+	"github.com/loadimpact/k6/stats/influxdb"
+	"github.com/loadimpact/k6/stats/json"
+	"github.com/loadimpact/k6/stats/prometheus"
+	"github.com/pkg/errors"
+)
+
+// parseCollector splits a -o/--out value, e.g. "influxdb=http://example.com",
+// into its collector type and the (possibly empty) argument passed to it.
+func parseCollector(s string) (t, arg string) {
+	parts := strings.SplitN(s, "=", 2)
+	t = parts[0]
+	if len(parts) == 2 {
+		arg = parts[1]
+	}
+	return
+}
+
+// newCollector instantiates the lib.Collector named by t, configuring it with arg.
+func newCollector(t, arg string, src *lib.SourceData, conf Config) (lib.Collector, error) {
+	switch t {
+	case collectorJSON:
+		return json.New(arg)
+	case collectorInfluxDB:
+		return influxdb.New(arg, conf.Collectors.InfluxDB)
+	case collectorCloud:
+		return cloud.New(conf.Collectors.Cloud, src, conf.Options, Version)
+	case collectorPrometheus:
+		return prometheus.New(arg, conf.Collectors.Prometheus)
+	default:
+		return nil, errors.Errorf("unknown output type: %s", t)
+	}
+}
@@ -0,0 +1,162 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/stats/cloud"
+	"github.com/loadimpact/k6/stats/influxdb"
+	"github.com/loadimpact/k6/stats/prometheus"
+	"github.com/spf13/pflag"
+	null "gopkg.in/guregu/null.v3"
+)
+
+// configFlagSet lists the flags that configure how a test is run, as
+// opposed to optionFlagSet's script options (VUs, duration, stages, ...).
+var configFlagSet = func() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.StringArrayVarP(&configOut, "out", "o", nil,
+		"`uri` for an external metrics database, can be set multiple times")
+	flags.BoolVar(&configLinger, "linger", false, "keep the API server alive past the end of the test")
+	flags.BoolVar(&configNoUsageReport, "no-usage-report", false, "don't send anonymous usage reports to k6")
+	flags.BoolVar(&configNoThresholds, "no-thresholds", false, "don't run thresholds")
+	return flags
+}()
+
+var (
+	configOut           []string
+	configLinger        bool
+	configNoUsageReport bool
+	configNoThresholds  bool
+)
+
+// Config is the set of options that control how `k6 run` drives a test,
+// layered from a config file, the script's own exported options, the
+// environment and the CLI flags (in that order of increasing precedence,
+// see runCmd's Apply() chain).
+type Config struct {
+	lib.Options
+
+	Out           []string  `json:"out" envconfig:"K6_OUT"`
+	Linger        null.Bool `json:"linger" envconfig:"K6_LINGER"`
+	NoUsageReport null.Bool `json:"noUsageReport" envconfig:"K6_NO_USAGE_REPORT"`
+	NoThresholds  null.Bool `json:"noThresholds" envconfig:"K6_NO_THRESHOLDS"`
+
+	Collectors struct {
+		InfluxDB   influxdb.Config   `json:"influxdb"`
+		Cloud      cloud.Config      `json:"cloud"`
+		Prometheus prometheus.Config `json:"prometheus"`
+	} `json:"collectors"`
+}
+
+// Apply overlays any value cfg has explicitly set on top of c, returning the result.
+func (c Config) Apply(cfg Config) Config {
+	c.Options = c.Options.Apply(cfg.Options)
+	if len(cfg.Out) > 0 {
+		c.Out = cfg.Out
+	}
+	if cfg.Linger.Valid {
+		c.Linger = cfg.Linger
+	}
+	if cfg.NoUsageReport.Valid {
+		c.NoUsageReport = cfg.NoUsageReport
+	}
+	if cfg.NoThresholds.Valid {
+		c.NoThresholds = cfg.NoThresholds
+	}
+	return c
+}
+
+// getConfig reads the subset of Config backed by CLI flags.
+func getConfig(flags *pflag.FlagSet) (Config, error) {
+	out, err := flags.GetStringArray("out")
+	if err != nil {
+		return Config{}, err
+	}
+	linger, err := flags.GetBool("linger")
+	if err != nil {
+		return Config{}, err
+	}
+	noUsageReport, err := flags.GetBool("no-usage-report")
+	if err != nil {
+		return Config{}, err
+	}
+	noThresholds, err := flags.GetBool("no-thresholds")
+	if err != nil {
+		return Config{}, err
+	}
+	return Config{
+		Out:           out,
+		Linger:        null.BoolFrom(linger),
+		NoUsageReport: null.BoolFrom(noUsageReport),
+		NoThresholds:  null.BoolFrom(noThresholds),
+	}, nil
+}
+
+// configDir returns the directory the on-disk config file lives in.
+func configDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "loadimpact", "k6"), nil
+}
+
+// readDiskConfig reads the JSON config file, if any, returning the path it
+// was (or would have been) read from alongside the parsed Config.
+func readDiskConfig() (Config, string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return Config{}, "", err
+	}
+	path := filepath.Join(dir, "config.json")
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Config{}, path, nil
+	}
+	if err != nil {
+		return Config{}, path, err
+	}
+
+	var conf Config
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return Config{}, path, err
+	}
+	return conf, path, nil
+}
+
+// readEnvConfig reads the Config fields that can be set through K6_* env
+// vars. A []string field like Out accepts a comma-separated list, e.g.
+// K6_OUT=influxdb=http://a,json=out.json.
+func readEnvConfig() (Config, error) {
+	var conf Config
+	if err := envconfig.Process("k6", &conf); err != nil {
+		return Config{}, err
+	}
+	return conf, nil
+}
@@ -0,0 +1,79 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/loadimpact/k6/core/local"
+	"github.com/loadimpact/k6/core/remote"
+	"github.com/loadimpact/k6/lib"
+	"github.com/pkg/errors"
+)
+
+const (
+	executionLocal   = "local"
+	executionSSH     = "ssh"
+	executionK8s     = "k8s"
+	executionCluster = "cluster"
+)
+
+// parseExecution splits a --execution flag value, e.g. "cluster=host1:50051,host2:50051",
+// into its mode and the (possibly empty) list of worker addresses.
+func parseExecution(s string) (mode string, workers []string) {
+	parts := strings.SplitN(s, "=", 2)
+	mode = parts[0]
+	if len(parts) == 2 && parts[1] != "" {
+		workers = strings.Split(parts[1], ",")
+	}
+	return
+}
+
+// newExecutor builds the lib.Executor that will drive r, either in this
+// process (the default) or, for "cluster", by farming the test out to
+// remote `k6 agent` workers reachable directly over HTTP. src is re-sent
+// to each worker verbatim so it can build its own Runner from the same
+// source/archive bytes.
+//
+// "ssh" and "k8s" are accepted by name (matching the --execution help
+// text) but aren't implemented yet - provisioning workers over an SSH
+// tunnel or a Kubernetes Job has no transport here, unlike "cluster",
+// which talks plain HTTP to an address the user already has reachable.
+// Silently routing them through the cluster transport would have k6 try
+// to speak HTTP to, say, an SSH daemon's port, so they're rejected
+// explicitly instead.
+func newExecutor(s string, src *lib.SourceData, r lib.Runner) (lib.Executor, error) {
+	mode, workers := parseExecution(s)
+	switch mode {
+	case "", executionLocal:
+		return local.New(r), nil
+	case executionSSH, executionK8s:
+		return nil, errors.Errorf("--execution=%s is not implemented yet; use --execution=cluster=host:port,... "+
+			"against a running `k6 agent` instead", mode)
+	case executionCluster:
+		if len(workers) == 0 {
+			return nil, errors.Errorf("--execution=%s requires at least one worker address", mode)
+		}
+		return remote.New(workers, src.Filename, src.Data, r)
+	default:
+		return nil, errors.Errorf("unknown --execution mode: %s", mode)
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExecution(t *testing.T) {
+	testdata := map[string]struct {
+		mode    string
+		workers []string
+	}{
+		"":                        {"", nil},
+		"local":                   {"local", nil},
+		"cluster=a:50051,b:50051": {"cluster", []string{"a:50051", "b:50051"}},
+		"ssh=host:22":             {"ssh", []string{"host:22"}},
+	}
+	for s, exp := range testdata {
+		mode, workers := parseExecution(s)
+		assert.Equal(t, exp.mode, mode)
+		assert.Equal(t, exp.workers, workers)
+	}
+}
+
+func TestNewExecutorRemoteRequiresWorkers(t *testing.T) {
+	_, err := newExecutor("cluster", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestNewExecutorUnknownMode(t *testing.T) {
+	_, err := newExecutor("bogus", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestNewExecutorSSHAndK8sAreNotImplemented(t *testing.T) {
+	for _, mode := range []string{"ssh=host:22", "k8s=pod:50051"} {
+		_, err := newExecutor(mode, nil, nil)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"time"
+
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/stats"
+)
+
+type jsonSummary struct {
+	Duration string                `json:"duration"`
+	Metrics  map[string]jsonMetric `json:"metrics"`
+	Root     jsonGroup             `json:"root_group"`
+}
+
+type jsonMetric struct {
+	Type       string             `json:"type"`
+	Values     map[string]float64 `json:"values,omitempty"`
+	Thresholds []jsonThreshold    `json:"thresholds,omitempty"`
+}
+
+type jsonThreshold struct {
+	Source string `json:"source"`
+	OK     bool   `json:"ok"`
+}
+
+// jsonGroup mirrors a lib.Group's check counts, recursively, so CI tooling
+// gets the same group/check breakdown the stdout summary prints.
+type jsonGroup struct {
+	Name   string      `json:"name"`
+	Checks []jsonCheck `json:"checks,omitempty"`
+	Groups []jsonGroup `json:"groups,omitempty"`
+}
+
+type jsonCheck struct {
+	Name   string `json:"name"`
+	Passes int64  `json:"passes"`
+	Fails  int64  `json:"fails"`
+}
+
+// exportSummaryJSON writes a machine-readable end-of-test summary - every
+// metric's aggregated values and threshold pass/fail state, plus the
+// group/check counts from root down - to path, so CI systems can consume
+// it without scraping stdout.
+func exportSummaryJSON(path string, metrics map[string]*stats.Metric, root *lib.Group, duration time.Duration) error {
+	summary := jsonSummary{
+		Duration: duration.String(),
+		Metrics:  make(map[string]jsonMetric, len(metrics)),
+		Root:     buildJSONGroup(root),
+	}
+	for name, m := range metrics {
+		jm := jsonMetric{Type: m.Type.String()}
+		if m.Sink != nil {
+			jm.Values = m.Sink.Format(duration)
+		}
+		for _, th := range m.Thresholds.Thresholds {
+			jm.Thresholds = append(jm.Thresholds, jsonThreshold{
+				Source: th.Source,
+				OK:     !th.LastFailed,
+			})
+		}
+		summary.Metrics[name] = jm
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func buildJSONGroup(group *lib.Group) jsonGroup {
+	jg := jsonGroup{Name: group.Name}
+	for _, check := range group.Checks {
+		jg.Checks = append(jg.Checks, jsonCheck{
+			Name:   check.Name,
+			Passes: check.Passes,
+			Fails:  check.Fails,
+		})
+	}
+	for _, sub := range group.Groups {
+		jg.Groups = append(jg.Groups, buildJSONGroup(sub))
+	}
+	return jg
+}
+
+type junitTestSuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:",chardata"`
+}
+
+// exportJUnit writes a JUnit XML report mapping every threshold and every
+// check (from root down through nested groups) to a <testcase>, so CI
+// reporters like Jenkins/GitLab/GitHub Actions can render them directly.
+func exportJUnit(path string, metrics map[string]*stats.Metric, root *lib.Group) error {
+	suite := junitTestSuite{Name: "k6"}
+
+	for name, m := range metrics {
+		for _, th := range m.Thresholds.Thresholds {
+			tc := junitTestCase{Name: name + ": " + th.Source}
+			if th.LastFailed {
+				tc.Failure = &junitFailure{Message: "threshold failed: " + th.Source}
+				suite.Failures++
+			}
+			suite.Tests++
+			suite.Cases = append(suite.Cases, tc)
+		}
+	}
+	addGroupChecks(&suite, root)
+
+	data, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append([]byte(xml.Header), data...)
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func addGroupChecks(suite *junitTestSuite, group *lib.Group) {
+	for _, check := range group.Checks {
+		tc := junitTestCase{Name: group.Name + ": " + check.Name}
+		if check.Fails > 0 {
+			tc.Failure = &junitFailure{Message: "check failed"}
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+	for _, sub := range group.Groups {
+		addGroupChecks(suite, sub)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/stats"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportSummaryJSONIncludesGroupAndCheckCounts(t *testing.T) {
+	root := &lib.Group{Name: "", Groups: map[string]*lib.Group{}}
+	sub, err := root.Group("my group")
+	require.NoError(t, err)
+	check, err := sub.Check("status is 200")
+	require.NoError(t, err)
+	check.Passes = 3
+	check.Fails = 1
+
+	metrics := map[string]*stats.Metric{
+		"http_reqs": {Name: "http_reqs", Type: stats.Counter},
+	}
+
+	dir, err := ioutil.TempDir("", "k6-export")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "summary.json")
+
+	require.NoError(t, exportSummaryJSON(path, metrics, root, 5*time.Second))
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	var summary jsonSummary
+	require.NoError(t, json.Unmarshal(data, &summary))
+
+	require.Len(t, summary.Root.Groups, 1)
+	group := summary.Root.Groups[0]
+	require.Equal(t, "my group", group.Name)
+	require.Len(t, group.Checks, 1)
+	require.Equal(t, int64(3), group.Checks[0].Passes)
+	require.Equal(t, int64(1), group.Checks[0].Fails)
+}
@@ -0,0 +1,92 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/stats"
+	log "github.com/sirupsen/logrus"
+)
+
+// multiCollector fans a single stream of samples out to several lib.Collectors,
+// so that a test run can feed more than one sink (e.g. InfluxDB and JSON) at once.
+type multiCollector struct {
+	collectors []lib.Collector
+}
+
+// newMultiCollector wraps the given collectors behind a single lib.Collector.
+func newMultiCollector(collectors ...lib.Collector) lib.Collector {
+	if len(collectors) == 1 {
+		return collectors[0]
+	}
+	return &multiCollector{collectors: collectors}
+}
+
+// Init runs Init() on every wrapped collector. A collector that fails to
+// initialize is dropped with a warning rather than aborting the whole run.
+func (mc *multiCollector) Init() error {
+	var live []lib.Collector
+	for _, c := range mc.collectors {
+		if err := c.Init(); err != nil {
+			log.WithError(err).Warn("Collector failed to initialize, skipping it")
+			continue
+		}
+		live = append(live, c)
+	}
+	mc.collectors = live
+	return nil
+}
+
+// Run starts every wrapped collector in its own goroutine. A collector that
+// exits (for whatever reason) doesn't stop the others or the test itself.
+func (mc *multiCollector) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(len(mc.collectors))
+	for _, c := range mc.collectors {
+		go func(c lib.Collector) {
+			defer wg.Done()
+			c.Run(ctx)
+		}(c)
+	}
+	wg.Wait()
+}
+
+// Collect fans samples out to every wrapped collector.
+func (mc *multiCollector) Collect(samples []stats.Sample) {
+	for _, c := range mc.collectors {
+		c.Collect(samples)
+	}
+}
+
+// Link joins the Link() of every wrapped collector for display in the banner.
+func (mc *multiCollector) Link() string {
+	var links []string
+	for _, c := range mc.collectors {
+		if l := c.Link(); l != "" {
+			links = append(links, l)
+		}
+	}
+	return strings.Join(links, ", ")
+}
@@ -38,9 +38,9 @@ import (
 
 	"github.com/loadimpact/k6/api"
 	"github.com/loadimpact/k6/core"
-	"github.com/loadimpact/k6/core/local"
 	"github.com/loadimpact/k6/js"
 	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/har"
 	"github.com/loadimpact/k6/loader"
 	"github.com/loadimpact/k6/ui"
 	"github.com/pkg/errors"
@@ -53,13 +53,19 @@ import (
 const (
 	typeJS      = "js"
 	typeArchive = "archive"
+	typeHAR     = "har"
 
-	collectorInfluxDB = "influxdb"
-	collectorJSON     = "json"
-	collectorCloud    = "cloud"
+	collectorInfluxDB   = "influxdb"
+	collectorJSON       = "json"
+	collectorCloud      = "cloud"
+	collectorPrometheus = "prometheus"
 )
 
 var runType = os.Getenv("K6_TYPE")
+var execution = executionLocal
+var summaryExportPath string
+var junitExportPath string
+var harOnly []string
 
 // runCmd represents the run command.
 var runCmd = &cobra.Command{
@@ -151,9 +157,13 @@ a commandline interface for interacting with it.`,
 		// Write options back to the runner too.
 		r.SetOptions(conf.Options)
 
-		// Create an engine with a local executor, wrapping the Runner.
+		// Create an engine with the selected executor, wrapping the Runner.
 		fmt.Fprintf(stdout, "%s   engine\r", initBar.String())
-		engine, err := core.NewEngine(local.New(r), conf.Options)
+		executor, err := newExecutor(execution, src, r)
+		if err != nil {
+			return err
+		}
+		engine, err := core.NewEngine(executor, conf.Options)
 		if err != nil {
 			return err
 		}
@@ -163,18 +173,28 @@ a commandline interface for interacting with it.`,
 			engine.NoThresholds = conf.NoThresholds.Bool
 		}
 
-		// Create a collector and assign it to the engine if requested.
+		// Create the collectors and assign them to the engine if requested. A
+		// collector that fails to construct is dropped with a warning rather
+		// than aborting the run. With two or more collectors, newMultiCollector's
+		// own Init() does the same for one that constructs fine but fails to
+		// initialize; a lone collector is used as is, and its Init() error aborts.
 		fmt.Fprintf(stdout, "%s   collector\r", initBar.String())
-		if conf.Out.Valid {
-			t, arg := parseCollector(conf.Out.String)
+		var collectors []lib.Collector
+		for _, out := range conf.Out {
+			t, arg := parseCollector(out)
 			collector, err := newCollector(t, arg, src, conf)
 			if err != nil {
-				return err
+				log.WithError(err).WithField("out", out).Warn("Couldn't create collector, skipping it")
+				continue
 			}
-			if err := collector.Init(); err != nil {
+			collectors = append(collectors, collector)
+		}
+		if len(collectors) > 0 {
+			mc := newMultiCollector(collectors...)
+			if err := mc.Init(); err != nil {
 				return err
 			}
-			engine.Collector = collector
+			engine.Collector = mc
 		}
 
 		// Create an API server.
@@ -190,13 +210,17 @@ a commandline interface for interacting with it.`,
 			out := "-"
 			link := ""
 			if engine.Collector != nil {
-				out = conf.Out.String
+				out = strings.Join(conf.Out, ", ")
 				if l := engine.Collector.Link(); l != "" {
 					link = " (" + l + ")"
 				}
 			}
 
-			fmt.Fprintf(stdout, "  execution: %s\n", ui.ValueColor.Sprint("local"))
+			executionMode, _ := parseExecution(execution)
+			if executionMode == "" {
+				executionMode = executionLocal
+			}
+			fmt.Fprintf(stdout, "  execution: %s\n", ui.ValueColor.Sprint(executionMode))
 			fmt.Fprintf(stdout, "     output: %s%s\n", ui.ValueColor.Sprint(out), ui.ExtraColor.Sprint(link))
 			fmt.Fprintf(stdout, "     script: %s\n", ui.ValueColor.Sprint(filename))
 			fmt.Fprintf(stdout, "\n")
@@ -371,6 +395,19 @@ a commandline interface for interacting with it.`,
 			fmt.Fprintf(stdout, "\n")
 		}
 
+		// Write out any requested CI artifacts, regardless of --quiet.
+		if summaryExportPath != "" {
+			root := engine.Executor.GetRunner().GetDefaultGroup()
+			if err := exportSummaryJSON(summaryExportPath, engine.Metrics, root, engine.Executor.GetTime()); err != nil {
+				log.WithError(err).Error("Couldn't write summary export")
+			}
+		}
+		if junitExportPath != "" {
+			if err := exportJUnit(junitExportPath, engine.Metrics, engine.Executor.GetRunner().GetDefaultGroup()); err != nil {
+				log.WithError(err).Error("Couldn't write JUnit export")
+			}
+		}
+
 		if conf.Linger.Bool {
 			log.Info("Linger set; waiting for Ctrl+C...")
 			<-sigC
@@ -389,7 +426,14 @@ func init() {
 	runCmd.Flags().SortFlags = false
 	runCmd.Flags().AddFlagSet(optionFlagSet)
 	runCmd.Flags().AddFlagSet(configFlagSet)
-	runCmd.Flags().StringVarP(&runType, "type", "t", runType, "override file `type`, \"js\" or \"archive\"")
+	runCmd.Flags().StringVarP(&runType, "type", "t", runType, "override file `type`, \"js\", \"archive\" or \"har\"")
+	runCmd.Flags().StringVar(&execution, "execution", execution,
+		"`mode` to execute in, \"local\" or \"cluster\"=host:port,... against a running `k6 agent`"+
+			" (\"ssh\"/\"k8s\" are reserved, not yet implemented)")
+	runCmd.Flags().StringVar(&summaryExportPath, "summary-export", "", "output the end-of-test summary report to JSON `file`")
+	runCmd.Flags().StringVar(&junitExportPath, "junit", "", "output a JUnit XML report of checks and thresholds to `file`")
+	runCmd.Flags().StringSliceVar(&harOnly, "har-only", nil,
+		"when running a .har file, only replay requests to these `host`s")
 }
 
 // Reads a source file from any supported destination.
@@ -426,6 +470,16 @@ func newRunner(src *lib.SourceData, typ string, fs afero.Fs) (lib.Runner, error)
 		default:
 			return nil, errors.Errorf("archive requests unsupported runner: %s", arc.Type)
 		}
+	case typeHAR:
+		h, err := har.Parse(src.Data)
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing HAR")
+		}
+		jsSrc, err := har.Convert(h, har.ConvertOptions{Only: harOnly, ThinkTime: true})
+		if err != nil {
+			return nil, errors.Wrap(err, "converting HAR to a script")
+		}
+		return js.New(&lib.SourceData{Filename: src.Filename, Data: jsSrc}, fs)
 	default:
 		return nil, errors.Errorf("unknown -t/--type: %s", typ)
 	}
@@ -435,5 +489,8 @@ func detectType(data []byte) string {
 	if _, err := tar.NewReader(bytes.NewReader(data)).Next(); err == nil {
 		return typeArchive
 	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`{"log":`)) {
+		return typeHAR
+	}
 	return typeJS
 }
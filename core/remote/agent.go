@@ -0,0 +1,193 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package remote
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/loadimpact/k6/core"
+	"github.com/loadimpact/k6/core/local"
+	"github.com/loadimpact/k6/js"
+	"github.com/loadimpact/k6/lib"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+// Agent is the worker-side counterpart of Executor: it exposes the
+// /v1/run, /v1/status, /v1/pause and /v1/scale endpoints a coordinator
+// talks to, running the actual test locally and reporting its progress.
+type Agent struct {
+	mutex  sync.Mutex
+	engine *core.Engine
+	cancel context.CancelFunc
+}
+
+// NewAgent creates an idle Agent, ready to be Served.
+func NewAgent() *Agent {
+	return &Agent{}
+}
+
+// Serve runs the agent's HTTP endpoint on addr until ctx is cancelled.
+func (a *Agent) Serve(ctx context.Context, addr string) error {
+	server := &http.Server{Addr: addr, Handler: a}
+	errC := make(chan error, 1)
+	go func() { errC <- server.ListenAndServe() }()
+	select {
+	case <-ctx.Done():
+		return server.Close()
+	case err := <-errC:
+		if err == http.ErrServerClosed {
+			return nil
+		}
+		return err
+	}
+}
+
+// ServeHTTP dispatches to the agent's four endpoints.
+func (a *Agent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/v1/run":
+		a.handleRun(w, r)
+	case "/v1/status":
+		a.handleStatus(w, r)
+	case "/v1/pause":
+		a.handlePause(w, r)
+	case "/v1/scale":
+		a.handleScale(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (a *Agent) handleRun(w http.ResponseWriter, r *http.Request) {
+	var req runRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	runner, err := buildRunner(req.Data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	runner.SetOptions(req.Options)
+
+	engine, err := core.NewEngine(local.New(runner), req.Options)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if req.VUsMax > 0 {
+		if err := engine.Executor.SetVUsMax(req.VUsMax); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	a.mutex.Lock()
+	if a.cancel != nil {
+		a.cancel()
+	}
+	a.engine = engine
+	a.cancel = cancel
+	a.mutex.Unlock()
+
+	go func() {
+		if err := engine.Run(ctx); err != nil {
+			log.WithError(err).Warn("Remote-worker engine exited with an error")
+		}
+	}()
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (a *Agent) handleStatus(w http.ResponseWriter, r *http.Request) {
+	engine := a.currentEngine()
+	if engine == nil {
+		http.Error(w, "no run in progress", http.StatusNotFound)
+		return
+	}
+
+	status := statusResponse{
+		Running:    engine.Executor.IsRunning(),
+		Paused:     engine.Executor.IsPaused(),
+		Iterations: engine.Executor.GetIterations(),
+		TimeMS:     engine.Executor.GetTime().Nanoseconds() / int64(time.Millisecond),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+func (a *Agent) handlePause(w http.ResponseWriter, r *http.Request) {
+	var req pauseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if engine := a.currentEngine(); engine != nil {
+		engine.Executor.SetPaused(req.Paused)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (a *Agent) handleScale(w http.ResponseWriter, r *http.Request) {
+	var req scaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if engine := a.currentEngine(); engine != nil {
+		if err := engine.Executor.SetVUsMax(req.VUsMax); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (a *Agent) currentEngine() *core.Engine {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.engine
+}
+
+// buildRunner turns the raw bytes a coordinator shipped over into a Runner:
+// an archive if the data tars up cleanly, a plain JS script otherwise.
+// Unlike `k6 run`, it does not detect HAR recordings.
+func buildRunner(data []byte) (lib.Runner, error) {
+	if _, err := tar.NewReader(bytes.NewReader(data)).Next(); err == nil {
+		arc, err := lib.ReadArchive(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return js.NewFromArchive(arc)
+	}
+	return js.New(&lib.SourceData{Filename: "worker.js", Data: data}, afero.NewOsFs())
+}
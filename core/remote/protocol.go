@@ -0,0 +1,55 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package remote
+
+import "github.com/loadimpact/k6/lib"
+
+// The coordinator and its workers speak plain JSON over HTTP; these are the
+// request/response bodies for the four endpoints a worker exposes.
+
+// runRequest ships everything a worker needs to start a test: the source
+// (or archive) bytes that were loaded on the coordinator, under the same
+// filename, plus the merged options and the VU ceiling to scale up to.
+type runRequest struct {
+	Filename string      `json:"filename"`
+	Data     []byte      `json:"data"`
+	Options  lib.Options `json:"options"`
+	VUsMax   int64       `json:"vus_max"`
+}
+
+// statusResponse is a worker's current progress, polled periodically by the
+// coordinator to drive the aggregate progress bar.
+type statusResponse struct {
+	Running    bool   `json:"running"`
+	Paused     bool   `json:"paused"`
+	Iterations uint64 `json:"iterations"`
+	TimeMS     int64  `json:"time_ms"`
+}
+
+// pauseRequest is sent to /v1/pause to pause or resume a worker's run.
+type pauseRequest struct {
+	Paused bool `json:"paused"`
+}
+
+// scaleRequest is sent to /v1/scale to change a worker's VU ceiling mid-run.
+type scaleRequest struct {
+	VUsMax int64 `json:"vus_max"`
+}
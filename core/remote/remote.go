@@ -0,0 +1,313 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package remote implements a lib.Executor that coordinates a test run
+// across a set of remote k6 worker nodes (started with `k6 agent`) instead
+// of running VUs in this process.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/loadimpact/k6/lib"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	null "gopkg.in/guregu/null.v3"
+)
+
+const pollInterval = 500 * time.Millisecond
+
+// worker is this coordinator's view of a single remote k6 agent.
+type worker struct {
+	addr   string
+	client *http.Client
+
+	mutex      sync.RWMutex
+	iterations uint64
+	t          time.Duration
+	paused     bool
+	running    bool
+}
+
+// Executor drives a Runner by shipping its source to a set of `k6 agent`
+// workers and aggregating the status they report back, rather than running
+// VUs locally.
+type Executor struct {
+	r        lib.Runner
+	filename string
+	data     []byte
+	workers  []*worker
+
+	vusMax int64
+}
+
+// New creates an Executor that will distribute r's execution across addrs.
+// filename/data are the same source (or archive) bytes that were loaded on
+// the coordinator, re-sent to each worker so it can build its own Runner.
+func New(addrs []string, filename string, data []byte, r lib.Runner) (*Executor, error) {
+	if len(addrs) == 0 {
+		return nil, errors.New("remote execution requires at least one worker address")
+	}
+	workers := make([]*worker, len(addrs))
+	for i, addr := range addrs {
+		workers[i] = &worker{addr: addr, client: &http.Client{Timeout: 10 * time.Second}}
+	}
+	return &Executor{r: r, filename: filename, data: data, workers: workers}, nil
+}
+
+// Run dispatches the test to every worker and blocks polling their status
+// until ctx is done or every worker has stopped running. A worker that
+// fails to dispatch or goes unreachable doesn't abort the others; the run
+// only errors out if every worker failed.
+func (e *Executor) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	errs := make(chan error, len(e.workers))
+
+	wg.Add(len(e.workers))
+	for _, w := range e.workers {
+		go func(w *worker) {
+			defer wg.Done()
+			if err := w.run(ctx, e.filename, e.data, e.r.GetOptions(), e.vusMax); err != nil {
+				errs <- errors.Wrapf(err, "worker %s", w.addr)
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	failed := 0
+	var lastErr error
+	for err := range errs {
+		failed++
+		lastErr = err
+		log.WithError(err).Warn("Remote worker failed")
+	}
+	if failed == len(e.workers) {
+		return lastErr
+	}
+	return nil
+}
+
+// run ships data to the worker, then polls it until the context is
+// cancelled or the worker reports it has stopped running, keeping w's
+// fields fresh for the coordinator's GetTime/GetIterations/etc.
+func (w *worker) run(ctx context.Context, filename string, data []byte, options lib.Options, vusMax int64) error {
+	body, err := json.Marshal(runRequest{Filename: filename, Data: data, Options: options, VUsMax: vusMax})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, "http://"+w.addr+"/v1/run", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, "dispatching run")
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("worker rejected run: %s", resp.Status)
+	}
+
+	w.mutex.Lock()
+	w.running = true
+	w.mutex.Unlock()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			w.mutex.Lock()
+			w.running = false
+			w.mutex.Unlock()
+			return nil
+		case <-ticker.C:
+			status, err := w.poll()
+			if err != nil {
+				log.WithError(err).WithField("worker", w.addr).Warn("Couldn't poll worker status")
+				continue
+			}
+			w.mutex.Lock()
+			w.iterations = status.Iterations
+			w.t = time.Duration(status.TimeMS) * time.Millisecond
+			w.paused = status.Paused
+			w.running = status.Running
+			w.mutex.Unlock()
+			if !status.Running {
+				return nil
+			}
+		}
+	}
+}
+
+func (w *worker) poll() (*statusResponse, error) {
+	resp, err := w.client.Get("http://" + w.addr + "/v1/status")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var status statusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func (w *worker) setPaused(paused bool) error {
+	body, err := json.Marshal(pauseRequest{Paused: paused})
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Post("http://"+w.addr+"/v1/pause", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (w *worker) setVUsMax(max int64) error {
+	body, err := json.Marshal(scaleRequest{VUsMax: max})
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Post("http://"+w.addr+"/v1/scale", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// GetRunner returns the Runner being executed.
+func (e *Executor) GetRunner() lib.Runner {
+	return e.r
+}
+
+// GetVUsMax returns the VU ceiling every worker has been told to scale up to.
+func (e *Executor) GetVUsMax() int64 {
+	return e.vusMax
+}
+
+// SetVUsMax proxies a new VU ceiling to every worker; this is also how the
+// REST API's scale command reaches remote workers, since api.ListenAndServe
+// talks to the lib.Executor interface rather than to local.Executor directly.
+func (e *Executor) SetVUsMax(max int64) error {
+	e.vusMax = max
+	for _, w := range e.workers {
+		w := w
+		go func() {
+			if err := w.setVUsMax(max); err != nil {
+				log.WithError(err).WithField("worker", w.addr).Warn("Couldn't scale worker")
+			}
+		}()
+	}
+	return nil
+}
+
+// GetStages returns the stages configured for the run.
+func (e *Executor) GetStages() []lib.Stage {
+	return e.r.GetOptions().Stages
+}
+
+// GetEndTime returns the configured run duration, if any.
+func (e *Executor) GetEndTime() lib.NullDuration {
+	return e.r.GetOptions().Duration
+}
+
+// GetEndIterations returns the configured iteration count, if any.
+func (e *Executor) GetEndIterations() null.Int {
+	return e.r.GetOptions().Iterations
+}
+
+// GetTime returns the longest elapsed time reported by any worker.
+func (e *Executor) GetTime() time.Duration {
+	var max time.Duration
+	for _, w := range e.workers {
+		w.mutex.RLock()
+		if w.t > max {
+			max = w.t
+		}
+		w.mutex.RUnlock()
+	}
+	return max
+}
+
+// GetIterations returns the sum of iterations completed across all workers.
+func (e *Executor) GetIterations() uint64 {
+	var total uint64
+	for _, w := range e.workers {
+		w.mutex.RLock()
+		total += w.iterations
+		w.mutex.RUnlock()
+	}
+	return total
+}
+
+// IsRunning reports whether at least one worker is still executing.
+func (e *Executor) IsRunning() bool {
+	for _, w := range e.workers {
+		w.mutex.RLock()
+		running := w.running
+		w.mutex.RUnlock()
+		if running {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPaused reports whether every worker, running or not, is currently paused.
+func (e *Executor) IsPaused() bool {
+	for _, w := range e.workers {
+		w.mutex.RLock()
+		paused := w.paused
+		w.mutex.RUnlock()
+		if !paused {
+			return false
+		}
+	}
+	return true
+}
+
+// SetPaused proxies a pause/resume command to every worker; like
+// SetVUsMax, this is how the REST API's pause/resume commands reach remote
+// workers.
+func (e *Executor) SetPaused(paused bool) {
+	for _, w := range e.workers {
+		w.mutex.Lock()
+		w.paused = paused
+		w.mutex.Unlock()
+
+		w := w
+		go func() {
+			if err := w.setPaused(paused); err != nil {
+				log.WithError(err).WithField("worker", w.addr).Warn("Couldn't pause/resume worker")
+			}
+		}()
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/loadimpact/k6/js"
+	"github.com/loadimpact/k6/lib"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeWorker is a minimal stand-in for `k6 agent`, just enough to exercise
+// Executor's dispatch/poll loop.
+func fakeWorker(t *testing.T, iterations uint64) *httptest.Server {
+	var ran int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/run", func(w http.ResponseWriter, r *http.Request) {
+		atomic.StoreInt32(&ran, 1)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/v1/status", func(w http.ResponseWriter, r *http.Request) {
+		running := atomic.LoadInt32(&ran) == 1
+		_ = json.NewEncoder(w).Encode(statusResponse{
+			Running:    false, // finishes after the first poll
+			Iterations: iterations,
+			TimeMS:     1000,
+		})
+		_ = running
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestExecutorRunAggregatesWorkers(t *testing.T) {
+	w1 := fakeWorker(t, 10)
+	defer w1.Close()
+	w2 := fakeWorker(t, 15)
+	defer w2.Close()
+
+	addrs := []string{strings.TrimPrefix(w1.URL, "http://"), strings.TrimPrefix(w2.URL, "http://")}
+
+	r, err := js.New(&lib.SourceData{Filename: "test.js", Data: []byte(`export default function() {};`)}, afero.NewMemMapFs())
+	require.NoError(t, err)
+
+	e, err := New(addrs, "test.js", []byte(`export default function() {};`), r)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, e.Run(ctx))
+
+	assert.EqualValues(t, 25, e.GetIterations())
+	assert.Equal(t, 1*time.Second, e.GetTime())
+}
+
+func TestNewRequiresWorkers(t *testing.T) {
+	_, err := New(nil, "test.js", nil, nil)
+	assert.Error(t, err)
+}
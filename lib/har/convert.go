@@ -0,0 +1,162 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package har
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// skippedHeaders are stripped out of a recorded request before it's
+// emitted into the generated script: HTTP/2 pseudo-headers (chrome://net
+// and most DevTools HAR exports include these in the headers list) aren't
+// valid HTTP/1.1 header names, and hop-by-hop headers are either set by
+// the HTTP client itself or no longer apply once the body is re-encoded.
+var skippedHeaders = map[string]bool{
+	":authority":        true,
+	":method":           true,
+	":path":             true,
+	":scheme":           true,
+	"host":              true,
+	"content-length":    true,
+	"connection":        true,
+	"keep-alive":        true,
+	"transfer-encoding": true,
+}
+
+// ConvertOptions controls how a HAR recording is turned into a JS scenario.
+type ConvertOptions struct {
+	// Only restricts the generated scenario to requests against these hostnames.
+	Only []string
+	// ThinkTime replays the recorded send+wait+receive delay between requests
+	// on the same page when true; otherwise requests fire back to back.
+	ThinkTime bool
+}
+
+// Convert synthesizes a k6 JS script that replays h's entries, grouped by
+// the page they were recorded under and preserving headers, cookies and
+// bodies, so a recording can be run with `k6 run recording.har` directly.
+func Convert(h *HAR, opts ConvertOptions) ([]byte, error) {
+	entries := h.Only(opts.Only)
+
+	pages := make(map[string][]Entry)
+	var order []string
+	for _, e := range entries {
+		if _, ok := pages[e.Pageref]; !ok {
+			order = append(order, e.Pageref)
+		}
+		pages[e.Pageref] = append(pages[e.Pageref], e)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("import http from \"k6/http\";\n")
+	buf.WriteString("import { group, sleep } from \"k6\";\n\n")
+	buf.WriteString("export default function() {\n")
+
+	for _, pageref := range order {
+		title := pageTitle(h, pageref)
+		fmt.Fprintf(&buf, "\tgroup(%s, function() {\n", jsString(title))
+		reqs := pages[pageref]
+		for i, e := range reqs {
+			writeRequest(&buf, e)
+			if opts.ThinkTime && i < len(reqs)-1 {
+				if think := thinkTime(e.Timings); think > 0 {
+					fmt.Fprintf(&buf, "\t\tsleep(%.3f);\n", think)
+				}
+			}
+		}
+		buf.WriteString("\t});\n")
+	}
+
+	buf.WriteString("}\n")
+	return buf.Bytes(), nil
+}
+
+// thinkTime sums the send/wait/receive phases of a HAR entry's timings into
+// a sleep() duration, in seconds. Per the HAR 1.2 spec, -1 marks a phase
+// as "not applicable" rather than zero-duration, so it's excluded instead
+// of being allowed to corrupt the sum.
+func thinkTime(t Timings) float64 {
+	var sum float64
+	for _, phase := range []float64{t.Send, t.Wait, t.Receive} {
+		if phase > 0 {
+			sum += phase
+		}
+	}
+	return sum / 1000
+}
+
+func pageTitle(h *HAR, pageref string) string {
+	for _, p := range h.Log.Pages {
+		if p.ID == pageref {
+			return p.Title
+		}
+	}
+	return pageref
+}
+
+func writeRequest(buf *bytes.Buffer, e Entry) {
+	headers := make(map[string]string, len(e.Request.Headers))
+	for _, h := range e.Request.Headers {
+		if skippedHeaders[strings.ToLower(h.Name)] {
+			continue
+		}
+		headers[h.Name] = h.Value
+	}
+	for _, c := range e.Request.Cookies {
+		headers["Cookie"] = appendCookie(headers["Cookie"], c)
+	}
+
+	params := fmt.Sprintf(`{ headers: %s }`, jsObject(headers))
+
+	switch e.Request.Method {
+	case "GET", "HEAD":
+		fmt.Fprintf(buf, "\t\thttp.request(%s, %s, null, %s);\n",
+			jsString(e.Request.Method), jsString(e.Request.URL), params)
+	default:
+		body := ""
+		if e.Request.PostData != nil {
+			body = e.Request.PostData.Text
+		}
+		fmt.Fprintf(buf, "\t\thttp.request(%s, %s, %s, %s);\n",
+			jsString(e.Request.Method), jsString(e.Request.URL), jsString(body), params)
+	}
+}
+
+func appendCookie(existing string, c NameValue) string {
+	pair := c.Name + "=" + c.Value
+	if existing == "" {
+		return pair
+	}
+	return existing + "; " + pair
+}
+
+func jsString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func jsObject(m map[string]string) string {
+	b, _ := json.Marshal(m)
+	return string(b)
+}
@@ -0,0 +1,85 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package har
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertStripsPseudoAndHopByHopHeaders(t *testing.T) {
+	h := &HAR{Log: Log{
+		Entries: []Entry{
+			{
+				Pageref: "page_1",
+				Request: Request{
+					Method: "GET",
+					URL:    "https://example.com/",
+					Headers: []NameValue{
+						{Name: ":authority", Value: "example.com"},
+						{Name: ":method", Value: "GET"},
+						{Name: ":path", Value: "/"},
+						{Name: ":scheme", Value: "https"},
+						{Name: "Host", Value: "example.com"},
+						{Name: "Content-Length", Value: "0"},
+						{Name: "Connection", Value: "keep-alive"},
+						{Name: "Accept", Value: "text/html"},
+					},
+				},
+			},
+		},
+	}}
+
+	out, err := Convert(h, ConvertOptions{})
+	assert.NoError(t, err)
+
+	script := string(out)
+	for _, bad := range []string{":authority", ":method", ":path", ":scheme", "Host", "Content-Length", "Connection"} {
+		assert.NotContains(t, script, bad)
+	}
+	assert.Contains(t, script, "Accept")
+}
+
+func TestConvertPreservesDeleteRequestBody(t *testing.T) {
+	h := &HAR{Log: Log{
+		Entries: []Entry{
+			{
+				Pageref: "page_1",
+				Request: Request{
+					Method:   "DELETE",
+					URL:      "https://example.com/items",
+					PostData: &PostData{MimeType: "application/json", Text: `{"ids":[1,2,3]}`},
+				},
+			},
+		},
+	}}
+
+	out, err := Convert(h, ConvertOptions{})
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), `{\"ids\":[1,2,3]}`)
+}
+
+func TestThinkTimeExcludesNotApplicableSentinel(t *testing.T) {
+	assert.Equal(t, 0.03, thinkTime(Timings{Send: 10, Wait: 20, Receive: -1}))
+	assert.Equal(t, float64(0), thinkTime(Timings{Send: -1, Wait: -1, Receive: -1}))
+	assert.Equal(t, 0.1, thinkTime(Timings{Send: 50, Wait: 50, Receive: 0}))
+}
@@ -0,0 +1,117 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package har parses HTTP Archive (HAR 1.2) recordings and synthesizes a k6
+// JS script from them, so a load test can be bootstrapped straight from a
+// browser recording without running the separate har-to-k6 converter first.
+package har
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+// HAR is the root object of a HAR 1.2 file.
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log holds every page and request/response pair recorded by the browser.
+type Log struct {
+	Pages   []Page  `json:"pages"`
+	Entries []Entry `json:"entries"`
+}
+
+// Page groups the entries that were loaded as part of the same navigation.
+type Page struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Started string `json:"startedDateTime"`
+}
+
+// Entry is a single request/response pair, including its timings.
+type Entry struct {
+	Pageref string   `json:"pageref"`
+	Started string   `json:"startedDateTime"`
+	Time    float64  `json:"time"`
+	Request Request  `json:"request"`
+	Timings Timings  `json:"timings"`
+}
+
+// Request describes the HTTP request side of an Entry.
+type Request struct {
+	Method      string       `json:"method"`
+	URL         string       `json:"url"`
+	Headers     []NameValue  `json:"headers"`
+	Cookies     []NameValue  `json:"cookies"`
+	PostData    *PostData    `json:"postData,omitempty"`
+}
+
+// PostData is the (optional) request body.
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// Timings records how long each phase of the request took; Send+Wait+Receive
+// is used as the think-time before the next request on the same page.
+type Timings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// NameValue is the header/cookie representation used throughout a HAR file.
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Parse decodes a raw HAR file.
+func Parse(data []byte) (*HAR, error) {
+	var h HAR
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// Only returns the h.Log.Entries whose URL's hostname is in the given
+// allow-list. A nil or empty list means "no filtering": h.Log.Entries itself
+// is returned, not a copy.
+func (h *HAR) Only(hosts []string) []Entry {
+	if len(hosts) == 0 {
+		return h.Log.Entries
+	}
+	allowed := make(map[string]bool, len(hosts))
+	for _, host := range hosts {
+		allowed[host] = true
+	}
+
+	var entries []Entry
+	for _, e := range h.Log.Entries {
+		u, err := url.Parse(e.Request.URL)
+		if err != nil || !allowed[u.Hostname()] {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
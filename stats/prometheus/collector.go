@@ -0,0 +1,195 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package prometheus exposes k6's metrics on a Prometheus /metrics scrape
+// endpoint instead of (or alongside) pushing them to an external sink.
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/loadimpact/k6/stats"
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+// trendBuckets are the histogram buckets Trend metrics are exported with.
+// k6 Trend samples (http_req_duration and friends) are float64
+// *milliseconds*, not seconds, so these are scoped accordingly rather than
+// using prom.DefBuckets (which assumes second-scale observations).
+var trendBuckets = []float64{1, 5, 10, 25, 50, 75, 100, 250, 500, 750, 1000, 2500, 5000, 10000, 30000}
+
+// Config holds the settings read from the environment/config file for the
+// "prometheus" collector, layered under conf.Collectors.
+type Config struct {
+	// Namespace prefixes every exported metric name, e.g. "k6_http_reqs".
+	Namespace string `json:"namespace" envconfig:"K6_PROMETHEUS_NAMESPACE"`
+}
+
+// Collector translates k6 samples into Prometheus metrics and serves them
+// on a /metrics endpoint for an external Prometheus server to scrape.
+type Collector struct {
+	addr      string
+	namespace string
+	registry  *prom.Registry
+
+	mutex    sync.Mutex
+	counters map[string]*prom.CounterVec
+	gauges   map[string]*prom.GaugeVec
+	trends   map[string]*prom.HistogramVec
+
+	server *http.Server
+}
+
+// New creates a Collector that will listen on addr (default ":9090") once
+// Run is called. Each Collector gets its own Registry, rather than
+// registering into Prometheus's global default, so that creating more than
+// one (e.g. across tests, or repeated `k6 run`s in the same process)
+// doesn't panic with a duplicate-registration error.
+func New(addr string, conf Config) (*Collector, error) {
+	if addr == "" {
+		addr = ":9090"
+	}
+	return &Collector{
+		addr:      addr,
+		namespace: conf.Namespace,
+		registry:  prom.NewRegistry(),
+		counters:  make(map[string]*prom.CounterVec),
+		gauges:    make(map[string]*prom.GaugeVec),
+		trends:    make(map[string]*prom.HistogramVec),
+	}, nil
+}
+
+// Init is a no-op; metrics are registered lazily as samples for them arrive.
+func (c *Collector) Init() error {
+	return nil
+}
+
+// Run starts the /metrics HTTP server and blocks until ctx is cancelled or
+// the server fails to start. Either way, a problem here is logged and
+// returned to, not panicked on - this collector failing shouldn't tear
+// down metrics collection for any other -o sink, let alone the test itself.
+func (c *Collector) Run(ctx context.Context) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(c.registry, promhttp.HandlerOpts{}))
+	c.server = &http.Server{Addr: c.addr, Handler: mux}
+
+	errC := make(chan error, 1)
+	go func() { errC <- c.server.ListenAndServe() }()
+
+	select {
+	case err := <-errC:
+		if err != nil && err != http.ErrServerClosed {
+			log.WithError(err).Error("Prometheus collector couldn't start its /metrics server")
+		}
+	case <-ctx.Done():
+		_ = c.server.Close()
+	}
+}
+
+// Link returns the address the scrape endpoint is served on.
+func (c *Collector) Link() string {
+	return fmt.Sprintf("http://%s/metrics", c.addr)
+}
+
+// Collect records each sample under a Prometheus metric named after its k6
+// metric, labeled with its group, tags and (for Trends) exposed as a
+// histogram so quantiles can be computed server-side.
+func (c *Collector) Collect(samples []stats.Sample) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, sample := range samples {
+		name := c.namespace + sample.Metric.Name
+		labels := prom.Labels{
+			"group":  sample.Tags["group"],
+			"tag":    sampleTag(sample),
+			"status": sample.Tags["status"],
+		}
+
+		switch sample.Metric.Type {
+		case stats.Counter:
+			c.counterFor(name, labels).Add(sample.Value)
+		case stats.Gauge:
+			c.gaugeFor(name, labels).Set(sample.Value)
+		case stats.Rate:
+			c.counterFor(name, labels).Add(sample.Value)
+		case stats.Trend:
+			c.trendFor(name, labels).Observe(sample.Value)
+		}
+	}
+}
+
+// sampleTag picks the tag that identifies what a sample is about: the
+// check name for check_* samples, falling back to the request URL for
+// http_req_* samples. stats.Sample doesn't carry a generic "name" tag -
+// that was the wrong key and left this label empty for virtually every
+// sample.
+func sampleTag(sample stats.Sample) string {
+	if check, ok := sample.Tags["check"]; ok {
+		return check
+	}
+	return sample.Tags["url"]
+}
+
+func (c *Collector) counterFor(name string, labels prom.Labels) prom.Counter {
+	vec, ok := c.counters[name]
+	if !ok {
+		vec = prom.NewCounterVec(prom.CounterOpts{Name: name}, labelNames(labels))
+		c.registry.MustRegister(vec)
+		c.counters[name] = vec
+	}
+	return vec.With(labels)
+}
+
+func (c *Collector) gaugeFor(name string, labels prom.Labels) prom.Gauge {
+	vec, ok := c.gauges[name]
+	if !ok {
+		vec = prom.NewGaugeVec(prom.GaugeOpts{Name: name}, labelNames(labels))
+		c.registry.MustRegister(vec)
+		c.gauges[name] = vec
+	}
+	return vec.With(labels)
+}
+
+func (c *Collector) trendFor(name string, labels prom.Labels) prom.Observer {
+	vec, ok := c.trends[name]
+	if !ok {
+		vec = prom.NewHistogramVec(prom.HistogramOpts{
+			Name:    name,
+			Buckets: trendBuckets,
+		}, labelNames(labels))
+		c.registry.MustRegister(vec)
+		c.trends[name] = vec
+	}
+	return vec.With(labels)
+}
+
+func labelNames(labels prom.Labels) []string {
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	return names
+}
@@ -0,0 +1,66 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2016 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/loadimpact/k6/stats"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectRegistersTrendSeries(t *testing.T) {
+	c, err := New("", Config{})
+	require.NoError(t, err)
+
+	metric := &stats.Metric{Name: "http_req_duration", Type: stats.Trend}
+	c.Collect([]stats.Sample{
+		{Metric: metric, Value: 120, Tags: map[string]string{"group": "", "url": "https://example.com/", "status": "200"}},
+	})
+
+	count := testutil.CollectAndCount(c.registry, "http_req_duration")
+	require.Equal(t, 1, count)
+}
+
+func TestSampleTagPrefersCheckOverURL(t *testing.T) {
+	require.Equal(t, "https://example.com/", sampleTag(stats.Sample{
+		Tags: map[string]string{"url": "https://example.com/"},
+	}))
+	require.Equal(t, "status is 200", sampleTag(stats.Sample{
+		Tags: map[string]string{"url": "https://example.com/", "check": "status is 200"},
+	}))
+}
+
+func TestNewCollectorsUseIndependentRegistries(t *testing.T) {
+	a, err := New("", Config{})
+	require.NoError(t, err)
+	b, err := New("", Config{})
+	require.NoError(t, err)
+
+	metric := &stats.Metric{Name: "http_reqs", Type: stats.Counter}
+	sample := []stats.Sample{{Metric: metric, Value: 1, Tags: map[string]string{"group": "", "url": "https://example.com/", "status": "200"}}}
+
+	require.NotPanics(t, func() {
+		a.Collect(sample)
+		b.Collect(sample)
+	})
+}